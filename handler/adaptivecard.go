@@ -0,0 +1,170 @@
+package handler
+
+// CardFormat selects which payload shape SendCard produces. Office 365
+// connectors expect a MessageCard; the newer Power Automate "Workflows"
+// webhooks reject that and require an Adaptive Card instead.
+type CardFormat string
+
+const (
+	// CardFormatMessageCard is the classic Office 365 connector payload.
+	CardFormatMessageCard CardFormat = "messagecard"
+	// CardFormatAdaptive is the Power Automate Workflows payload.
+	CardFormatAdaptive CardFormat = "adaptive"
+)
+
+// Card is satisfied by every supported card payload so callers can build and
+// send one without knowing which CardFormat was requested.
+type Card interface {
+	// CreateCard renders the card from p. A nil tmpls uses DefaultTemplates.
+	CreateCard(p PrometheusAlertMessage, tmpls *Templates) error
+	// SendCard delivers the card to url. receiver labels the metrics
+	// recorded for the attempt.
+	SendCard(receiver, url string) error
+}
+
+// NewCard returns the Card implementation matching format, defaulting to the
+// classic MessageCard when format is empty or unrecognized.
+func NewCard(format CardFormat) Card {
+	if format == CardFormatAdaptive {
+		return new(AdaptiveCardMessage)
+	}
+	return new(TeamsMessageCard)
+}
+
+// AdaptiveCardMessage is the top level envelope Power Automate Workflows
+// webhooks expect: {"type":"message","attachments":[{...}]}.
+// The Documentation is in https://adaptivecards.io/explorer/AdaptiveCard.html
+type AdaptiveCardMessage struct {
+	Type        string               `json:"type"`
+	Attachments []AdaptiveAttachment `json:"attachments"`
+
+	// alertCount is the number of alerts the card represents, i.e. the
+	// number of per-alert Container sections in Body. It's tracked
+	// separately (and unexported, so it's never marshaled) rather than
+	// derived from Body's length, since Body also holds the title and
+	// optional summary/text TextBlocks.
+	alertCount int
+}
+
+// AdaptiveAttachment wraps the Adaptive Card body with the content type
+// Workflows uses to recognize it.
+type AdaptiveAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard is the Adaptive Card v1.4 body.
+type AdaptiveCard struct {
+	Type    string                 `json:"type"`
+	Schema  string                 `json:"$schema"`
+	Version string                 `json:"version"`
+	Body    []AdaptiveCardBodyItem `json:"body"`
+}
+
+// AdaptiveCardBodyItem is a single element of an Adaptive Card's Body.
+// Only the TextBlock, FactSet and Container element types that
+// prometheus-msteams needs are modeled here.
+type AdaptiveCardBodyItem struct {
+	Type   string                 `json:"type"`
+	Text   string                 `json:"text,omitempty"`
+	Weight string                 `json:"weight,omitempty"`
+	Size   string                 `json:"size,omitempty"`
+	Wrap   bool                   `json:"wrap,omitempty"`
+	Color  string                 `json:"color,omitempty"`
+	Style  string                 `json:"style,omitempty"`
+	Items  []AdaptiveCardBodyItem `json:"items,omitempty"`
+	Facts  []AdaptiveCardFact     `json:"facts,omitempty"`
+}
+
+// AdaptiveCardFact is a single entry in an Adaptive Card FactSet.
+type AdaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// CreateCard creates the AdaptiveCardMessage based on values gathered from
+// PrometheusAlertMessage, mirroring TeamsMessageCard.CreateCard. A nil
+// tmpls falls back to DefaultTemplates.
+func (c *AdaptiveCardMessage) CreateCard(p PrometheusAlertMessage, tmpls *Templates) error {
+	const (
+		colorResolved = "good"
+		colorFiring   = "attention"
+		colorUnknown  = "default"
+	)
+	if tmpls == nil {
+		tmpls = DefaultTemplates()
+	}
+	var containerColor string
+	switch p.Status {
+	case "resolved":
+		containerColor = colorResolved
+	case "firing":
+		containerColor = colorFiring
+	default:
+		containerColor = colorUnknown
+	}
+
+	title := AdaptiveCardBodyItem{
+		Type:   "TextBlock",
+		Text:   render(tmpls.Title, p),
+		Weight: "bolder",
+		Size:   "medium",
+		Wrap:   true,
+	}
+
+	var sections []AdaptiveCardBodyItem
+	for _, alert := range p.Alerts {
+		var facts []AdaptiveCardFact
+		for key, val := range alert.Annotations {
+			facts = append(facts, AdaptiveCardFact{Title: key, Value: val})
+		}
+		for key, val := range alert.Labels {
+			facts = append(facts, AdaptiveCardFact{Title: key, Value: val})
+		}
+		sections = append(sections, AdaptiveCardBodyItem{
+			Type:  "Container",
+			Style: containerColor,
+			Items: []AdaptiveCardBodyItem{
+				{
+					Type: "TextBlock",
+					Text: render(tmpls.ActivityTitle, AlertContext{p, alert}),
+					Wrap: true,
+				},
+				{
+					Type:  "FactSet",
+					Facts: facts,
+				},
+			},
+		})
+	}
+
+	body := []AdaptiveCardBodyItem{title}
+	if summary := render(tmpls.Summary, p); summary != "" {
+		body = append(body, AdaptiveCardBodyItem{Type: "TextBlock", Text: summary, Wrap: true})
+	}
+	if text := render(tmpls.Text, p); text != "" {
+		body = append(body, AdaptiveCardBodyItem{Type: "TextBlock", Text: text, Wrap: true})
+	}
+	body = append(body, sections...)
+
+	c.Type = "message"
+	c.alertCount = len(sections)
+	c.Attachments = []AdaptiveAttachment{
+		{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: AdaptiveCard{
+				Type:    "AdaptiveCard",
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Version: "1.4",
+				Body:    body,
+			},
+		},
+	}
+	return nil
+}
+
+// SendCard sends the JSON encoded AdaptiveCardMessage to the given webhook
+// URL, retrying on transient failures and Teams throttling.
+func (c *AdaptiveCardMessage) SendCard(receiver, url string) error {
+	return postCard(receiver, url, c, retryConfig)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the top level structure of the YAML file passed via --config.
+// It declares one or more Teams receivers and the routing rules used to
+// pick which receiver(s) handle a given PrometheusAlertMessage.
+type Config struct {
+	Receivers []ReceiverConfig `yaml:"receivers"`
+	Routes    []RouteConfig    `yaml:"routes"`
+}
+
+// ReceiverConfig names a single MS Teams incoming webhook destination.
+// The Name is referenced by RouteConfig.Receiver and is also used to build
+// the HTTP path the receiver is served on, e.g. /alertmanager/<name>.
+type ReceiverConfig struct {
+	Name       string         `yaml:"name"`
+	WebhookURL string         `yaml:"webhook_url"`
+	Templates  *TemplateFiles `yaml:"templates,omitempty"`
+}
+
+// RouteConfig matches incoming alert labels against Match and, on success,
+// forwards the alert to Receiver. Routes are evaluated in order and all
+// matching routes are used, so a single alert can be delivered to several
+// receivers.
+type RouteConfig struct {
+	Receiver string            `yaml:"receiver"`
+	Match    map[string]string `yaml:"match"`
+}
+
+// LoadConfig reads and parses the YAML file at path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config %s: %v", path, err)
+	}
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %v", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c *Config) validate() error {
+	names := make(map[string]bool, len(c.Receivers))
+	for _, r := range c.Receivers {
+		if r.Name == "" {
+			return fmt.Errorf("config: receiver with empty name")
+		}
+		if r.WebhookURL == "" {
+			return fmt.Errorf("config: receiver %q has no webhook_url", r.Name)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("config: duplicate receiver name %q", r.Name)
+		}
+		names[r.Name] = true
+	}
+	for _, route := range c.Routes {
+		if !names[route.Receiver] {
+			return fmt.Errorf("config: route references unknown receiver %q", route.Receiver)
+		}
+	}
+	return nil
+}
+
+// WebhookURL returns the configured webhook URL for the named receiver, or
+// false if no such receiver exists.
+func (c *Config) WebhookURL(name string) (string, bool) {
+	for _, r := range c.Receivers {
+		if r.Name == name {
+			return r.WebhookURL, true
+		}
+	}
+	return "", false
+}
+
+// MatchReceivers returns the name of every distinct receiver with at least
+// one matching route, in the order its first matching route appears. A
+// route matches when all of its Match labels are present in labels with
+// equal values. A receiver targeted by several matching routes is still
+// only returned once, so callers don't enqueue duplicate deliveries to it.
+func (c *Config) MatchReceivers(labels map[string]string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	for _, route := range c.Routes {
+		if !routeMatches(route, labels) || seen[route.Receiver] {
+			continue
+		}
+		seen[route.Receiver] = true
+		matched = append(matched, route.Receiver)
+	}
+	return matched
+}
+
+func routeMatches(route RouteConfig, labels map[string]string) bool {
+	for k, v := range route.Match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
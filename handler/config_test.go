@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg: Config{
+				Receivers: []ReceiverConfig{
+					{Name: "team-a", WebhookURL: "https://example.com/a"},
+				},
+				Routes: []RouteConfig{
+					{Receiver: "team-a", Match: map[string]string{"team": "a"}},
+				},
+			},
+		},
+		{
+			name: "empty receiver name",
+			cfg: Config{
+				Receivers: []ReceiverConfig{{Name: "", WebhookURL: "https://example.com/a"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing webhook url",
+			cfg: Config{
+				Receivers: []ReceiverConfig{{Name: "team-a"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate receiver name",
+			cfg: Config{
+				Receivers: []ReceiverConfig{
+					{Name: "team-a", WebhookURL: "https://example.com/a"},
+					{Name: "team-a", WebhookURL: "https://example.com/b"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "route references unknown receiver",
+			cfg: Config{
+				Receivers: []ReceiverConfig{{Name: "team-a", WebhookURL: "https://example.com/a"}},
+				Routes:    []RouteConfig{{Receiver: "team-b", Match: map[string]string{"team": "b"}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigMatchReceivers(t *testing.T) {
+	cfg := Config{
+		Receivers: []ReceiverConfig{
+			{Name: "team-a", WebhookURL: "https://example.com/a"},
+			{Name: "team-b", WebhookURL: "https://example.com/b"},
+		},
+		Routes: []RouteConfig{
+			{Receiver: "team-a", Match: map[string]string{"severity": "critical"}},
+			{Receiver: "team-a", Match: map[string]string{"team": "a"}},
+			{Receiver: "team-b", Match: map[string]string{"team": "b"}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{
+			name:   "no route matches",
+			labels: map[string]string{"team": "c"},
+			want:   nil,
+		},
+		{
+			name:   "single matching route",
+			labels: map[string]string{"team": "b"},
+			want:   []string{"team-b"},
+		},
+		{
+			name:   "several routes matching the same receiver are deduped",
+			labels: map[string]string{"team": "a", "severity": "critical"},
+			want:   []string{"team-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.MatchReceivers(tt.labels)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("MatchReceivers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,13 +4,36 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-// CardCounter displays in the logs
-var CardCounter int
+// legacyReceiverName labels metrics and queue jobs for the single-webhook
+// /alertmanager endpoint, which predates named receivers.
+const legacyReceiverName = "default"
+
+// retryConfig holds the delivery retry parameters used by every SendCard
+// implementation, configured once at startup from the RETRY_* env vars.
+var retryConfig = RetryConfigFromEnv()
+
+// defaultQueue bounds the number of in-flight deliveries so a burst of
+// alerts can't spawn unbounded goroutines when Teams is degraded.
+var defaultQueue = NewSendQueue(QueueConfigFromEnv())
+
+// httpClient is used for every card delivery attempt. A hung Teams endpoint
+// would otherwise block a queue worker indefinitely, since retries only run
+// after the current attempt returns.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// defaultTemplates is the process-wide template set returned by
+// DefaultTemplates. main() may replace it via SetDefaultTemplates with
+// operator-supplied template files before the server starts serving
+// requests.
+var defaultTemplates = builtinTemplates()
 
 // PrometheusAlertMessage is the request body that Prometheus sent via Generic Webhook
 // The Documentation is in https://prometheus.io/docs/alerting/configuration/#webhook_config
@@ -71,46 +94,95 @@ func AlertManagerHandler(w http.ResponseWriter, r *http.Request) {
 	err := decoder.Decode(&p)
 	if err != nil {
 		msg := fmt.Sprintf("Error: encoding message: %v", err)
-		log.Println(msg)
+		Logger.Error("decoding alert message failed", "error", err)
 		http.Error(w, msg, http.StatusBadRequest)
 		return
 	}
-	// For Debugging, display the Request in JSON Format
-	log.Println("Request received")
-	json.NewEncoder(os.Stdout).Encode(p)
+	Logger.Debug("alert batch received", "receiver", legacyReceiverName, "alerts", len(p.Alerts), "payload", p)
+	alertBatchesReceived.Inc()
 	// Create the Card
-	c := new(TeamsMessageCard)
-	c.CreateCard(p)
-	// For Debugging, display the Request Body to send in JSON Format
-	log.Println("Creating a card")
-	json.NewEncoder(os.Stdout).Encode(c)
-	err = c.SendCard()
-	if err != nil {
-		log.Println(err)
+	c := NewCard(CardFormat(os.Getenv("CARD_FORMAT")))
+	c.CreateCard(p, defaultTemplates)
+	Logger.Debug("card created", "receiver", legacyReceiverName, "card", c)
+	if !defaultQueue.Enqueue(c, legacyReceiverName, os.Getenv("TEAMS_INCOMING_WEBHOOK_URL")) {
+		cardsDropped.WithLabelValues(legacyReceiverName).Inc()
+		Logger.Warn("send queue is full, dropping alert", "receiver", legacyReceiverName)
+		http.Error(w, "Error: send queue is full", http.StatusServiceUnavailable)
 	}
 }
 
-// SendCard sends the JSON Encoded TeamsMessageCard
-func (c *TeamsMessageCard) SendCard() error {
-	b := new(bytes.Buffer)
-	json.NewEncoder(b).Encode(c)
-	url := os.Getenv("TEAMS_INCOMING_WEBHOOK_URL")
-	resp, err := http.Post(url, "application/json", b)
-	if err != nil {
-		log.Println(err)
-		return err
+// SendCard sends the JSON Encoded TeamsMessageCard to the given webhook URL,
+// retrying on transient failures and Teams throttling.
+func (c *TeamsMessageCard) SendCard(receiver, url string) error {
+	return postCard(receiver, url, c, retryConfig)
+}
+
+// postCard JSON-encodes card and POSTs it to url, accepting both the classic
+// Office 365 connector response (200 OK) and the Power Automate Workflows
+// response (202 Accepted) as success. On a retryable failure (429 or 5xx) it
+// backs off per rc and tries again, honoring any Retry-After header, up to
+// rc.MaxAttempts. receiver labels the Prometheus metrics recorded for the
+// attempt.
+func postCard(receiver, url string, card interface{}, rc RetryConfig) error {
+	start := time.Now()
+	timer := prometheus.NewTimer(cardSendDuration.WithLabelValues(receiver))
+	defer timer.ObserveDuration()
+
+	var lastErr error
+	for attempt := 0; attempt < rc.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			cardSendRetries.WithLabelValues(receiver).Inc()
+			Logger.Debug("retrying card send", "receiver", receiver, "attempt", attempt+1)
+		}
+		b := new(bytes.Buffer)
+		json.NewEncoder(b).Encode(card)
+		resp, err := httpClient.Post(url, "application/json", b)
+		if err != nil {
+			lastErr = err
+			Logger.Warn("card send request failed", "receiver", receiver, "error", err)
+			time.Sleep(rc.backoff(attempt, 0))
+			continue
+		}
+		statusCode := resp.StatusCode
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+		teamsResponseCodes.WithLabelValues(receiver, strconv.Itoa(statusCode)).Inc()
+		if statusCode == http.StatusOK || statusCode == http.StatusAccepted {
+			cardsSent.WithLabelValues(receiver, statusClass(statusCode)).Inc()
+			Logger.Info("card sent", "receiver", receiver, "alerts", cardAlertCount(card), "status", statusCode, "duration", time.Since(start))
+			return nil
+		}
+		lastErr = fmt.Errorf("Error: %s", resp.Status)
+		if !isRetryable(statusCode) {
+			cardsSent.WithLabelValues(receiver, statusClass(statusCode)).Inc()
+			Logger.Error("card send failed", "receiver", receiver, "status", statusCode, "duration", time.Since(start))
+			return lastErr
+		}
+		time.Sleep(rc.backoff(attempt, retryAfter))
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Error: %s", resp.Status)
+	cardsSent.WithLabelValues(receiver, "failed").Inc()
+	Logger.Error("card send failed", "receiver", receiver, "error", lastErr, "duration", time.Since(start))
+	return lastErr
+}
+
+// cardAlertCount reports how many alerts a card represents, for the "card
+// sent" log line. Unrecognized card types report 0 rather than failing.
+func cardAlertCount(card interface{}) int {
+	switch c := card.(type) {
+	case *TeamsMessageCard:
+		return len(c.Sections)
+	case *AdaptiveCardMessage:
+		return c.alertCount
+	default:
+		return 0
 	}
-	CardCounter++
-	log.Printf("Total Card sent since uptime: %d\n", CardCounter)
-	return nil
 }
 
-// CreateCard creates the TeamsMessageCard based on values gathered from PrometheusAlertMessage
-func (c *TeamsMessageCard) CreateCard(p PrometheusAlertMessage) error {
+// CreateCard creates the TeamsMessageCard based on values gathered from
+// PrometheusAlertMessage. Title, Summary, Text and each section's
+// ActivityTitle are rendered from tmpls; a nil tmpls falls back to
+// DefaultTemplates, reproducing the card's original hardcoded output.
+func (c *TeamsMessageCard) CreateCard(p PrometheusAlertMessage, tmpls *Templates) error {
 	const (
 		messageType   = "MessageCard"
 		context       = "http://schema.org/extensions"
@@ -118,6 +190,9 @@ func (c *TeamsMessageCard) CreateCard(p PrometheusAlertMessage) error {
 		colorFiring   = "8C1A1A"
 		colorUnknown  = "CCCCCC"
 	)
+	if tmpls == nil {
+		tmpls = DefaultTemplates()
+	}
 	c.Type = messageType
 	c.Context = context
 	switch p.Status {
@@ -128,17 +203,16 @@ func (c *TeamsMessageCard) CreateCard(p PrometheusAlertMessage) error {
 	default:
 		c.ThemeColor = colorUnknown
 	}
-	c.Title = fmt.Sprintf("Prometheus Alert (%s)", p.Status)
-	if value, notEmpty := p.CommonAnnotations["summary"]; notEmpty {
-		c.Summary = value
-	}
+	c.Title = render(tmpls.Title, p)
+	c.Summary = render(tmpls.Summary, p)
+	c.Text = render(tmpls.Text, p)
 	useMarkdown := false
 	if v := os.Getenv("MARKDOWN_ENABLED"); v == "yes" {
 		useMarkdown = true
 	}
 	for _, alert := range p.Alerts {
 		var s TeamsMessageCardSection
-		s.ActivityTitle = fmt.Sprintf("[%s](%s)", alert.Annotations["description"], p.ExternalURL)
+		s.ActivityTitle = render(tmpls.ActivityTitle, AlertContext{p, alert})
 		s.Markdown = useMarkdown
 		for key, val := range alert.Annotations {
 			s.Facts = append(s.Facts, TeamsMessageCardSectionFacts{key, val})
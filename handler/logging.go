@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is the package-wide structured logger used by AlertManagerHandler,
+// the Router and SendCard. main() replaces it via SetLogger, built from
+// NewLogger with the operator's --log.level/--log.format flags, before the
+// server starts handling requests.
+var Logger = slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+// SetLogger replaces the package-wide Logger.
+func SetLogger(l *slog.Logger) {
+	Logger = l
+}
+
+// NewLogger builds a slog.Logger at the given level ("debug", "info",
+// "warn", "error") and format ("logfmt", "json"). An unrecognized level
+// falls back to info; an unrecognized format falls back to logfmt.
+func NewLogger(level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stdout, opts))
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statusClass buckets an HTTP status code the way Teams delivery metrics
+// are labeled: "2xx", "4xx", "5xx", etc.
+func statusClass(code int) string {
+	return strconv.Itoa(code/100) + "xx"
+}
+
+var (
+	// alertBatchesReceived counts inbound Alertmanager webhook posts,
+	// before they're split into individual cards.
+	alertBatchesReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "prometheus_msteams_alert_batches_received_total",
+		Help: "Number of Prometheus alert batches received.",
+	})
+
+	// cardsSent counts card deliveries by receiver and outcome.
+	cardsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_msteams_cards_sent_total",
+		Help: "Number of cards sent to Teams, labeled by receiver and status class.",
+	}, []string{"receiver", "status_class"})
+
+	// teamsResponseCodes counts the raw HTTP status codes Teams returned.
+	teamsResponseCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_msteams_teams_response_codes_total",
+		Help: "HTTP status codes returned by the Teams webhook, labeled by receiver and code.",
+	}, []string{"receiver", "code"})
+
+	// cardSendRetries counts individual retry attempts, not including the
+	// first try.
+	cardSendRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_msteams_card_send_retries_total",
+		Help: "Number of retry attempts made while sending a card, labeled by receiver.",
+	}, []string{"receiver"})
+
+	// cardsDropped counts alerts that never made it onto the send queue
+	// because it was full.
+	cardsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "prometheus_msteams_cards_dropped_total",
+		Help: "Number of alerts dropped because the send queue was full, labeled by receiver.",
+	}, []string{"receiver"})
+
+	// cardSendDuration observes how long a full SendCard call (including
+	// retries) takes to either succeed or give up.
+	cardSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "prometheus_msteams_card_send_duration_seconds",
+		Help:    "Time spent sending a card to Teams, including retries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"receiver"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		alertBatchesReceived,
+		cardsSent,
+		teamsResponseCodes,
+		cardSendRetries,
+		cardsDropped,
+		cardSendDuration,
+	)
+}
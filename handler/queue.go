@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"os"
+	"strconv"
+)
+
+// QueueConfig sizes the bounded worker pool that delivers cards to Teams.
+type QueueConfig struct {
+	Capacity int
+	Workers  int
+}
+
+// DefaultQueueConfig is used whenever the corresponding QUEUE_* env var is
+// unset or invalid.
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{Capacity: 256, Workers: 4}
+}
+
+// QueueConfigFromEnv builds a QueueConfig from QUEUE_CAPACITY and
+// QUEUE_WORKERS, falling back to DefaultQueueConfig for anything unset or
+// unparsable.
+func QueueConfigFromEnv() QueueConfig {
+	cfg := DefaultQueueConfig()
+	if v, err := strconv.Atoi(os.Getenv("QUEUE_CAPACITY")); err == nil && v > 0 {
+		cfg.Capacity = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("QUEUE_WORKERS")); err == nil && v > 0 {
+		cfg.Workers = v
+	}
+	return cfg
+}
+
+// sendJob is a single queued card delivery.
+type sendJob struct {
+	card     Card
+	receiver string
+	url      string
+}
+
+// SendQueue is a bounded worker pool that runs card deliveries on a fixed
+// number of workers so a burst of alerts doesn't spawn unbounded goroutines
+// when Teams is degraded. Jobs that don't fit in the queue are dropped; the caller is
+// told so it can log or count the drop.
+type SendQueue struct {
+	jobs chan sendJob
+}
+
+// NewSendQueue creates a SendQueue sized by cfg and starts cfg.Workers
+// goroutines draining it.
+func NewSendQueue(cfg QueueConfig) *SendQueue {
+	q := &SendQueue{jobs: make(chan sendJob, cfg.Capacity)}
+	for i := 0; i < cfg.Workers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+// worker drains jobs and sends each card. SendCard already logs its own
+// failures (with status/duration context), so a failed send isn't logged
+// again here.
+func (q *SendQueue) worker() {
+	for job := range q.jobs {
+		job.card.SendCard(job.receiver, job.url)
+	}
+}
+
+// Enqueue queues card for delivery to url on behalf of receiver. It returns
+// false without blocking if the queue is full, so the caller can drop the
+// alert instead of piling up goroutines.
+func (q *SendQueue) Enqueue(card Card, receiver, url string) bool {
+	select {
+	case q.jobs <- sendJob{card: card, receiver: receiver, url: url}:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetryConfig controls how postCard retries a failed delivery to Teams.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      bool
+}
+
+// DefaultRetryConfig is used whenever the corresponding RETRY_* env var is
+// unset or invalid.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 5,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      true,
+	}
+}
+
+// RetryConfigFromEnv builds a RetryConfig from RETRY_MAX_ATTEMPTS,
+// RETRY_BASE_DELAY, RETRY_MAX_DELAY and RETRY_JITTER, falling back to
+// DefaultRetryConfig for anything unset or unparsable.
+func RetryConfigFromEnv() RetryConfig {
+	cfg := DefaultRetryConfig()
+	if v, err := strconv.Atoi(os.Getenv("RETRY_MAX_ATTEMPTS")); err == nil && v > 0 {
+		cfg.MaxAttempts = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("RETRY_BASE_DELAY")); err == nil && v > 0 {
+		cfg.BaseDelay = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("RETRY_MAX_DELAY")); err == nil && v > 0 {
+		cfg.MaxDelay = v
+	}
+	if v := os.Getenv("RETRY_JITTER"); v != "" {
+		cfg.Jitter = v != "false"
+	}
+	return cfg
+}
+
+// backoff returns how long to wait after the failed attempt number attempt
+// (0-based) before the next one, honoring retryAfter when the server
+// supplied one.
+func (rc RetryConfig) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return capDuration(retryAfter, rc.MaxDelay)
+	}
+	delay := rc.BaseDelay * time.Duration(1<<uint(attempt))
+	if rc.Jitter {
+		delay = time.Duration(float64(delay) * (0.5 + rand.Float64()))
+	}
+	return capDuration(delay, rc.MaxDelay)
+}
+
+func capDuration(d, max time.Duration) time.Duration {
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
+
+// isRetryable reports whether a response status code warrants another
+// attempt: Teams throttling (429) or a transient server-side failure (5xx).
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter parses the Retry-After header, which Teams sends either
+// as a number of seconds or an HTTP-date. It returns 0 when the header is
+// absent or unparsable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
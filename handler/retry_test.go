@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryConfigBackoff(t *testing.T) {
+	rc := RetryConfig{BaseDelay: time.Second, MaxDelay: 10 * time.Second, Jitter: false}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{name: "first attempt", attempt: 0, want: time.Second},
+		{name: "second attempt doubles", attempt: 1, want: 2 * time.Second},
+		{name: "capped at max delay", attempt: 10, want: 10 * time.Second},
+		{name: "retry-after overrides exponential delay", attempt: 0, retryAfter: 3 * time.Second, want: 3 * time.Second},
+		{name: "retry-after still capped at max delay", attempt: 0, retryAfter: 20 * time.Second, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rc.backoff(tt.attempt, tt.retryAfter); got != tt.want {
+				t.Fatalf("backoff(%d, %v) = %v, want %v", tt.attempt, tt.retryAfter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBackoffJitter(t *testing.T) {
+	rc := RetryConfig{BaseDelay: time.Second, MaxDelay: 10 * time.Second, Jitter: true}
+	for i := 0; i < 20; i++ {
+		d := rc.backoff(0, 0)
+		if d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("backoff() with jitter = %v, want within [0.5s, 1.5s]", d)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusBadRequest, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.status); got != tt.want {
+			t.Errorf("isRetryable(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Run("empty header", func(t *testing.T) {
+		if got := parseRetryAfter(""); got != 0 {
+			t.Fatalf("parseRetryAfter(\"\") = %v, want 0", got)
+		}
+	})
+
+	t.Run("seconds", func(t *testing.T) {
+		if got := parseRetryAfter("5"); got != 5*time.Second {
+			t.Fatalf("parseRetryAfter(\"5\") = %v, want 5s", got)
+		}
+	})
+
+	t.Run("http date", func(t *testing.T) {
+		when := time.Now().Add(2 * time.Minute)
+		got := parseRetryAfter(when.UTC().Format(http.TimeFormat))
+		if got <= 0 || got > 2*time.Minute {
+			t.Fatalf("parseRetryAfter(date) = %v, want roughly 2m", got)
+		}
+	})
+
+	t.Run("unparsable", func(t *testing.T) {
+		if got := parseRetryAfter("not-a-valid-value"); got != 0 {
+			t.Fatalf("parseRetryAfter(garbage) = %v, want 0", got)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// Router dispatches incoming Prometheus alerts to the MS Teams webhooks
+// declared in a Config, either by a fixed receiver name (one HTTP endpoint
+// per receiver) or by matching the alert's labels against the configured
+// routing rules.
+type Router struct {
+	config    *Config
+	templates map[string]*Templates
+}
+
+// NewRouter builds a Router over the given Config, resolving each
+// receiver's template overrides (falling back to DefaultTemplates on error
+// or when none are configured).
+func NewRouter(c *Config) *Router {
+	rt := &Router{config: c, templates: make(map[string]*Templates, len(c.Receivers))}
+	for _, r := range c.Receivers {
+		tmpls, err := LoadTemplates(r.Templates)
+		if err != nil {
+			Logger.Error("loading templates for receiver failed", "receiver", r.Name, "error", err)
+			tmpls = DefaultTemplates()
+		}
+		rt.templates[r.Name] = tmpls
+	}
+	return rt
+}
+
+func (rt *Router) templatesFor(name string) *Templates {
+	if t, ok := rt.templates[name]; ok {
+		return t
+	}
+	return DefaultTemplates()
+}
+
+// RegisterRoutes mounts one handler per configured receiver at
+// /alertmanager/<receiver-name>, mirroring how upstream alertmanager
+// receivers are named.
+func (rt *Router) RegisterRoutes(mux *http.ServeMux) {
+	for _, r := range rt.config.Receivers {
+		mux.HandleFunc("/alertmanager/"+r.Name, rt.ReceiverHandler(r.Name))
+	}
+	mux.HandleFunc("/alertmanager", rt.RoutedHandler)
+}
+
+// ReceiverHandler returns an http.HandlerFunc that sends every incoming
+// alert straight to the named receiver's webhook.
+func (rt *Router) ReceiverHandler(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := rt.config.WebhookURL(name); !ok {
+			http.Error(w, fmt.Sprintf("Error: unknown receiver %q", name), http.StatusNotFound)
+			return
+		}
+		p, err := decodeAlert(w, r)
+		if err != nil {
+			return
+		}
+		rt.send(w, p, []string{name})
+	}
+}
+
+// RoutedHandler matches the alert's common labels against the config's
+// routing rules and delivers it to every receiver whose route matches.
+func (rt *Router) RoutedHandler(w http.ResponseWriter, r *http.Request) {
+	p, err := decodeAlert(w, r)
+	if err != nil {
+		return
+	}
+	names := rt.config.MatchReceivers(p.CommonLabels)
+	if len(names) == 0 {
+		http.Error(w, "Error: no receiver matched the alert's labels", http.StatusBadRequest)
+		return
+	}
+	rt.send(w, p, names)
+}
+
+func decodeAlert(w http.ResponseWriter, r *http.Request) (PrometheusAlertMessage, error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Error: Only accepts POST requests.", http.StatusBadRequest)
+		return PrometheusAlertMessage{}, fmt.Errorf("method not allowed")
+	}
+	decoder := json.NewDecoder(r.Body)
+	var p PrometheusAlertMessage
+	if err := decoder.Decode(&p); err != nil {
+		msg := fmt.Sprintf("Error: encoding message: %v", err)
+		Logger.Error("decoding alert message failed", "error", err)
+		http.Error(w, msg, http.StatusBadRequest)
+		return PrometheusAlertMessage{}, err
+	}
+	alertBatchesReceived.Inc()
+	Logger.Debug("alert batch received", "alerts", len(p.Alerts), "payload", p)
+	return p, nil
+}
+
+// send builds one card per receiver (so each can use its own template
+// overrides) and enqueues it for delivery. Delivery itself (including
+// retries) happens on the SendQueue's workers, so this only reports whether
+// the alert was accepted for delivery, not whether it was actually sent.
+func (rt *Router) send(w http.ResponseWriter, p PrometheusAlertMessage, names []string) {
+	for _, name := range names {
+		url, ok := rt.config.WebhookURL(name)
+		if !ok {
+			Logger.Error("receiver has no webhook configured", "receiver", name)
+			continue
+		}
+		c := NewCard(CardFormat(os.Getenv("CARD_FORMAT")))
+		c.CreateCard(p, rt.templatesFor(name))
+		Logger.Debug("card created", "receiver", name, "card", c)
+		if !defaultQueue.Enqueue(c, name, url) {
+			cardsDropped.WithLabelValues(name).Inc()
+			Logger.Warn("send queue is full, dropping alert", "receiver", name)
+			http.Error(w, "Error: send queue is full", http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
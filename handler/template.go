@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Default templates reproduce the card exactly as it looked before template
+// support was added, so operators who don't supply their own keep the same
+// output.
+const (
+	defaultTitleTemplate         = `Prometheus Alert ({{ .Status }})`
+	defaultSummaryTemplate       = `{{ index .CommonAnnotations "summary" }}`
+	defaultActivityTitleTemplate = `[{{ index .Alert.Annotations "description" }}]({{ .ExternalURL }})`
+	defaultTextTemplate          = ``
+)
+
+// Templates holds the parsed text/template.Template used to render a card's
+// Title, Summary and Text, plus the per-alert ActivityTitle.
+type Templates struct {
+	Title         *template.Template
+	Summary       *template.Template
+	ActivityTitle *template.Template
+	Text          *template.Template
+}
+
+// TemplateFiles names the files a receiver supplies to override one or
+// more of the default templates. Any field left empty keeps the default.
+type TemplateFiles struct {
+	Title         string `yaml:"title"`
+	Summary       string `yaml:"summary"`
+	ActivityTitle string `yaml:"activity_title"`
+	Text          string `yaml:"text"`
+}
+
+// AlertContext is the data made available to the ActivityTitle template: the
+// full PrometheusAlertMessage plus the single Alert the section is for.
+type AlertContext struct {
+	PrometheusAlertMessage
+	Alert
+}
+
+// funcMap exposes a handful of Sprig-style helpers so operators can build
+// richer markdown without recompiling.
+func funcMap() template.FuncMap {
+	return template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"title": strings.Title,
+		"toJson": func(v interface{}) string {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return ""
+			}
+			return string(b)
+		},
+		"date": func(layout, ts string) string {
+			t, err := time.Parse(time.RFC3339, ts)
+			if err != nil {
+				return ts
+			}
+			return t.Format(layout)
+		},
+	}
+}
+
+// builtinTemplates parses the built-in templates that reproduce the card's
+// historical, hardcoded output.
+func builtinTemplates() *Templates {
+	return &Templates{
+		Title:         mustParse("title", defaultTitleTemplate),
+		Summary:       mustParse("summary", defaultSummaryTemplate),
+		ActivityTitle: mustParse("activityTitle", defaultActivityTitleTemplate),
+		Text:          mustParse("text", defaultTextTemplate),
+	}
+}
+
+// DefaultTemplates returns the templates used whenever a card or receiver
+// doesn't supply its own, i.e. the process-wide default set by main() from
+// --title-template and friends (or the built-ins, if none were given).
+func DefaultTemplates() *Templates {
+	return defaultTemplates
+}
+
+// SetDefaultTemplates replaces the process-wide default template set. It is
+// meant to be called once at startup, before the server begins handling
+// requests.
+func SetDefaultTemplates(t *Templates) {
+	defaultTemplates = t
+}
+
+func mustParse(name, text string) *template.Template {
+	t, err := template.New(name).Funcs(funcMap()).Parse(text)
+	if err != nil {
+		// The built-in templates are constants; a parse failure here is a
+		// programming error, not a runtime condition.
+		panic(err)
+	}
+	return t
+}
+
+// LoadTemplates builds a Templates set, parsing each file named in tf and
+// falling back to the process-wide DefaultTemplates for anything left
+// unset. A nil tf returns a copy of DefaultTemplates().
+func LoadTemplates(tf *TemplateFiles) (*Templates, error) {
+	base := DefaultTemplates()
+	tmpls := &Templates{
+		Title:         base.Title,
+		Summary:       base.Summary,
+		ActivityTitle: base.ActivityTitle,
+		Text:          base.Text,
+	}
+	if tf == nil {
+		return tmpls, nil
+	}
+	var err error
+	if tf.Title != "" {
+		if tmpls.Title, err = parseFile("title", tf.Title); err != nil {
+			return nil, err
+		}
+	}
+	if tf.Summary != "" {
+		if tmpls.Summary, err = parseFile("summary", tf.Summary); err != nil {
+			return nil, err
+		}
+	}
+	if tf.ActivityTitle != "" {
+		if tmpls.ActivityTitle, err = parseFile("activityTitle", tf.ActivityTitle); err != nil {
+			return nil, err
+		}
+	}
+	if tf.Text != "" {
+		if tmpls.Text, err = parseFile("text", tf.Text); err != nil {
+			return nil, err
+		}
+	}
+	return tmpls, nil
+}
+
+func parseFile(name, path string) (*template.Template, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(name).Funcs(funcMap()).Parse(string(b))
+}
+
+// render executes tmpl against data, logging and returning "" on failure so
+// a broken template degrades a card instead of failing delivery.
+func render(tmpl *template.Template, data interface{}) string {
+	if tmpl == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		Logger.Error("template render failed", "error", err)
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadTemplatesNilFallsBackToDefaults(t *testing.T) {
+	tmpls, err := LoadTemplates(nil)
+	if err != nil {
+		t.Fatalf("LoadTemplates(nil) error = %v", err)
+	}
+	def := DefaultTemplates()
+	if tmpls.Title != def.Title || tmpls.Summary != def.Summary ||
+		tmpls.ActivityTitle != def.ActivityTitle || tmpls.Text != def.Text {
+		t.Fatalf("LoadTemplates(nil) = %+v, want a copy of DefaultTemplates()", tmpls)
+	}
+}
+
+func TestLoadTemplatesOverridesOnlyGivenFiles(t *testing.T) {
+	dir := t.TempDir()
+	titlePath := filepath.Join(dir, "title.tmpl")
+	writeFile(t, titlePath, `Custom: {{ .Status }}`)
+
+	tmpls, err := LoadTemplates(&TemplateFiles{Title: titlePath})
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	def := DefaultTemplates()
+	if tmpls.Title == def.Title {
+		t.Fatalf("Title was not overridden")
+	}
+	if tmpls.Summary != def.Summary || tmpls.ActivityTitle != def.ActivityTitle || tmpls.Text != def.Text {
+		t.Fatalf("fields without an override file should keep the default template")
+	}
+
+	got := render(tmpls.Title, PrometheusAlertMessage{Status: "firing"})
+	if want := "Custom: firing"; got != want {
+		t.Fatalf("render(Title) = %q, want %q", got, want)
+	}
+}
+
+func TestLoadTemplatesMissingFile(t *testing.T) {
+	if _, err := LoadTemplates(&TemplateFiles{Title: filepath.Join(t.TempDir(), "missing.tmpl")}); err == nil {
+		t.Fatal("LoadTemplates() with a missing file, error = nil, want non-nil")
+	}
+}
+
+func TestRenderFallsBackOnNilOrBrokenTemplate(t *testing.T) {
+	if got := render(nil, nil); got != "" {
+		t.Fatalf("render(nil, ...) = %q, want \"\"", got)
+	}
+
+	broken := mustParse("broken", `{{ .NoSuchField.Nested }}`)
+	if got := render(broken, PrometheusAlertMessage{}); got != "" {
+		t.Fatalf("render(broken template) = %q, want \"\"", got)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+
+	"github.com/infonova/prometheus-msteams/handler"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	var (
+		configPath            = flag.String("config", "", "path to the YAML config file declaring receivers and routes")
+		addr                  = flag.String("addr", ":2000", "address to listen on")
+		titleTemplateFile     = flag.String("title-template", "", "path to a Go template file rendering the card title")
+		summaryTemplateFile   = flag.String("summary-template", "", "path to a Go template file rendering the card summary")
+		activityTitleTemplate = flag.String("activity-title-template", "", "path to a Go template file rendering each section's activity title")
+		textTemplateFile      = flag.String("text-template", "", "path to a Go template file rendering the card text")
+		logLevel              = flag.String("log.level", "info", "logging level: debug, info, warn or error")
+		logFormat             = flag.String("log.format", "logfmt", "log output format: logfmt or json")
+	)
+	flag.Parse()
+
+	logger := handler.NewLogger(*logLevel, *logFormat)
+	handler.SetLogger(logger)
+
+	tmpls, err := handler.LoadTemplates(&handler.TemplateFiles{
+		Title:         *titleTemplateFile,
+		Summary:       *summaryTemplateFile,
+		ActivityTitle: *activityTitleTemplate,
+		Text:          *textTemplateFile,
+	})
+	if err != nil {
+		logger.Error("loading templates failed", "error", err)
+		os.Exit(1)
+	}
+	handler.SetDefaultTemplates(tmpls)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	if *configPath != "" {
+		cfg, err := handler.LoadConfig(*configPath)
+		if err != nil {
+			logger.Error("loading config failed", "error", err)
+			os.Exit(1)
+		}
+		handler.NewRouter(cfg).RegisterRoutes(mux)
+	} else {
+		mux.HandleFunc("/alertmanager", handler.AlertManagerHandler)
+	}
+
+	logger.Info("listening", "addr", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logger.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}